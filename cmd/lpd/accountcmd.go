@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/livepeer/livepeer-swarm/livepeer/api"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// accountCommand groups the key management subcommands that upstream keeps
+// out of the swarm package proper: "lpd account new" and
+// "lpd account import".
+var accountCommand = cli.Command{
+	Name:  "account",
+	Usage: "manage swarm accounts",
+	Subcommands: []cli.Command{
+		{
+			Name:   "new",
+			Usage:  "create a new account",
+			Action: accountNew,
+			Flags:  lpdFlags,
+		},
+		{
+			Name:      "import",
+			Usage:     "import an unencrypted private key into the keystore",
+			Action:    accountImport,
+			Flags:     lpdFlags,
+			ArgsUsage: "<keyfile>",
+		},
+	},
+}
+
+func accountNew(ctx *cli.Context) error {
+	ks := keystoreFor(ctx)
+	passphrase, err := readPassphrase(true)
+	if err != nil {
+		return err
+	}
+	account, err := ks.NewAccount(passphrase)
+	if err != nil {
+		return fmt.Errorf("error creating account: %v", err)
+	}
+	fmt.Println("Address:", account.Address.Hex())
+	return nil
+}
+
+func accountImport(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("this command requires the path to an unencrypted private key file")
+	}
+	key, err := crypto.LoadECDSA(ctx.Args()[0])
+	if err != nil {
+		return fmt.Errorf("error loading private key: %v", err)
+	}
+	ks := keystoreFor(ctx)
+	passphrase, err := readPassphrase(true)
+	if err != nil {
+		return err
+	}
+	account, err := ks.ImportECDSA(key, passphrase)
+	if err != nil {
+		return fmt.Errorf("error importing account: %v", err)
+	}
+	fmt.Println("Address:", account.Address.Hex())
+	return nil
+}
+
+func keystoreFor(ctx *cli.Context) *keystore.KeyStore {
+	datadir := ctx.String("datadir")
+	if datadir == "" {
+		datadir = defaultDataDir()
+	}
+	return keystore.NewKeyStore(filepath.Join(datadir, "keystore"), keystore.StandardScryptN, keystore.StandardScryptP)
+}
+
+// resolveKeySource picks the api.KeySource backing the swarm/bzz key: if
+// addr is empty a fresh ephemeral key is minted and wrapped in a
+// MemoryKeySource (handy for the "lpd" default action during
+// development), otherwise the on-disk keystore is used and a passphrase
+// is read from the terminal for api.NewConfig to unlock it with. The
+// returned address is always the one NewConfig will actually derive
+// config.json's directory from, so callers can locate that file before
+// the key is unlocked.
+func resolveKeySource(datadir, addr string) (api.KeySource, common.Address, string, error) {
+	if addr == "" {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, common.Address{}, "", fmt.Errorf("error generating ephemeral key: %v", err)
+		}
+		return api.NewMemoryKeySource(key), crypto.PubkeyToAddress(key.PublicKey), "", nil
+	}
+
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		return nil, common.Address{}, "", err
+	}
+	keySource := api.NewKeystoreKeySource(filepath.Join(datadir, "keystore"))
+	return keySource, common.HexToAddress(addr), passphrase, nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/livepeer-swarm/livepeer/api"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// lpdFlags are the command line flags accepted by the default "lpd" action.
+// Flags take priority over every other configuration source.
+var lpdFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "datadir",
+		Usage: "data directory for the swarm datadir and keystore",
+		Value: defaultDataDir(),
+	},
+	cli.StringFlag{
+		Name:  "networkid",
+		Usage: "network identifier (integer, default 3 for the swarm testnet)",
+	},
+	cli.StringFlag{
+		Name:  "rtmpport",
+		Usage: "port the RTMP ingest server listens on",
+	},
+	cli.StringFlag{
+		Name:  "ffmpegpath",
+		Usage: "path to the ffmpeg binary used for transcoding",
+	},
+	cli.StringFlag{
+		Name:  "vodpath",
+		Usage: "directory VOD assets are stored under",
+	},
+	cli.StringFlag{
+		Name:  "account",
+		Usage: "address of the account to unlock and use as the bzz key",
+	},
+	cli.StringFlag{
+		Name:  "swapcontract",
+		Usage: "address of the swap payment contract",
+	},
+}
+
+// layeredConfig is the merged view of every configuration source, from
+// lowest to highest priority: built-in defaults, config.json, environment
+// variables, and finally command line flags.
+type layeredConfig struct {
+	DataDir      string
+	NetworkId    uint64
+	RTMPPort     string
+	FFMpegPath   string
+	VodPath      string
+	Account      string
+	SwapContract string
+}
+
+// defaultLayeredConfig returns the built-in defaults, the bottom of the
+// configuration hierarchy.
+func defaultLayeredConfig() *layeredConfig {
+	return &layeredConfig{
+		DataDir:    defaultDataDir(),
+		NetworkId:  3,
+		RTMPPort:   "1935",
+		FFMpegPath: "ffmpeg",
+		VodPath:    "vod",
+	}
+}
+
+// applyEnv overlays LPD_* environment variables onto cfg.
+func (cfg *layeredConfig) applyEnv() {
+	if v := os.Getenv("LPD_DATADIR"); v != "" {
+		cfg.DataDir = v
+	}
+	if v := os.Getenv("LPD_NETWORK_ID"); v != "" {
+		if id, err := parseUint64(v); err == nil {
+			cfg.NetworkId = id
+		}
+	}
+	if v := os.Getenv("LPD_RTMP_PORT"); v != "" {
+		cfg.RTMPPort = v
+	}
+	if v := os.Getenv("LPD_FFMPEG_PATH"); v != "" {
+		cfg.FFMpegPath = v
+	}
+	if v := os.Getenv("LPD_VOD_PATH"); v != "" {
+		cfg.VodPath = v
+	}
+	if v := os.Getenv("LPD_SWAP_CONTRACT"); v != "" {
+		cfg.SwapContract = v
+	}
+}
+
+// applyPersisted overlays the RTMPPort/FFMpegPath/VodPath/NetworkId last
+// written to datadir/bzz-<addr>/config.json onto cfg, if that file
+// exists. It sits between the built-in defaults and env vars in the
+// priority order, so it must run before applyEnv/applyFlags are
+// (re-)applied on top of it.
+func (cfg *layeredConfig) applyPersisted(datadir string, addr common.Address) {
+	confpath := filepath.Join(datadir, "bzz-"+common.Bytes2Hex(addr.Bytes()), "config.json")
+	data, err := ioutil.ReadFile(confpath)
+	if err != nil {
+		return
+	}
+
+	var persisted struct {
+		NetworkId  uint64
+		RTMPPort   string
+		FFMpegPath string
+		VodPath    string
+	}
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return
+	}
+
+	if persisted.NetworkId != 0 {
+		cfg.NetworkId = persisted.NetworkId
+	}
+	if persisted.RTMPPort != "" {
+		cfg.RTMPPort = persisted.RTMPPort
+	}
+	if persisted.FFMpegPath != "" {
+		cfg.FFMpegPath = persisted.FFMpegPath
+	}
+	if persisted.VodPath != "" {
+		cfg.VodPath = persisted.VodPath
+	}
+}
+
+// applyFlags overlays command line flags onto cfg. Flags are the highest
+// priority source and win over everything below them.
+func (cfg *layeredConfig) applyFlags(ctx *cli.Context) {
+	if ctx.IsSet("datadir") {
+		cfg.DataDir = ctx.String("datadir")
+	}
+	if ctx.IsSet("networkid") {
+		if id, err := parseUint64(ctx.String("networkid")); err == nil {
+			cfg.NetworkId = id
+		}
+	}
+	if ctx.IsSet("rtmpport") {
+		cfg.RTMPPort = ctx.String("rtmpport")
+	}
+	if ctx.IsSet("ffmpegpath") {
+		cfg.FFMpegPath = ctx.String("ffmpegpath")
+	}
+	if ctx.IsSet("vodpath") {
+		cfg.VodPath = ctx.String("vodpath")
+	}
+	if ctx.IsSet("account") {
+		cfg.Account = ctx.String("account")
+	}
+	if ctx.IsSet("swapcontract") {
+		cfg.SwapContract = ctx.String("swapcontract")
+	}
+}
+
+// buildConfig merges defaults, the on-disk config.json, environment
+// variables and command line flags, in that order (each tier overrides
+// the one before it), and turns the result into an api.Config ready to
+// hand to the swarm service.
+//
+// DataDir/Account have to be resolved first, from defaults/env/flags
+// only, since they determine where config.json lives. Once the account's
+// address is known, config.json is read as the next tier up from
+// defaults, and env/flags are re-applied on top of it so they keep
+// winning regardless of what's on disk.
+func buildConfig(ctx *cli.Context) (*api.Config, error) {
+	cfg := defaultLayeredConfig()
+	cfg.applyEnv()
+	cfg.applyFlags(ctx)
+
+	keySource, addr, passphrase, err := resolveKeySource(cfg.DataDir, cfg.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.applyPersisted(cfg.DataDir, addr)
+	cfg.applyEnv()
+	cfg.applyFlags(ctx)
+
+	return api.NewConfig(&api.Options{
+		Path:       cfg.DataDir,
+		Contract:   common.HexToAddress(cfg.SwapContract),
+		Address:    addr,
+		KeySource:  keySource,
+		Passphrase: passphrase,
+		NetworkId:  cfg.NetworkId,
+		RTMPPort:   cfg.RTMPPort,
+		FFMpegPath: cfg.FFMpegPath,
+		VodPath:    cfg.VodPath,
+	})
+}
+
+func defaultDataDir() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = "."
+	}
+	return home + "/.lpd"
+}
+
+func parseUint64(s string) (uint64, error) {
+	return strconv.ParseUint(s, 10, 64)
+}
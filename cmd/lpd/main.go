@@ -0,0 +1,70 @@
+// lpd is the Livepeer swarm daemon.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const lpdVersion = "0.1.0"
+
+var (
+	gitCommit string // set via linker flags
+)
+
+var app = cli.NewApp()
+
+func init() {
+	app.Action = lpd
+	app.Name = "lpd"
+	app.Usage = "Livepeer swarm daemon"
+	app.Version = versionString()
+	app.Flags = append(app.Flags, lpdFlags...)
+	app.Commands = []cli.Command{
+		versionCommand,
+		accountCommand,
+	}
+	app.Before = func(ctx *cli.Context) error {
+		return nil
+	}
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// lpd is the default action: it merges the layered configuration, boots a
+// node.Node with the swarm protocol registered as a service, and blocks
+// until the node is stopped.
+func lpd(ctx *cli.Context) error {
+	cfg, err := buildConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	stack, err := makeNode(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := stack.Start(); err != nil {
+		return fmt.Errorf("error starting node: %v", err)
+	}
+	glog.Infof("lpd: node started, rtmp port %v", cfg.RTMPPort)
+
+	stack.Wait()
+	return nil
+}
+
+func versionString() string {
+	if gitCommit != "" {
+		return fmt.Sprintf("%s-%s", lpdVersion, gitCommit[:8])
+	}
+	return lpdVersion
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/livepeer/livepeer-swarm/livepeer/api"
+	"github.com/livepeer/livepeer-swarm/livepeer/network"
+)
+
+// makeNode builds a node.Node with the swarm protocol registered as a
+// node.Service, the way upstream go-ethereum wires bzzd. IPC and
+// HTTP-RPC endpoints are enabled with their respective defaults so the
+// daemon is reachable the moment it starts.
+func makeNode(cfg *api.Config) (*node.Node, error) {
+	nodeCfg := &node.Config{
+		Name:    "lpd",
+		Version: lpdVersion,
+		DataDir: cfg.Path,
+		P2P: p2p.Config{
+			ListenAddr: ":" + cfg.Port,
+			MaxPeers:   25,
+		},
+		IPCPath:  "lpd.ipc",
+		HTTPHost: "127.0.0.1",
+		HTTPPort: 8501,
+	}
+
+	stack, err := node.New(nodeCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		return network.NewSwarm(cfg)
+	}); err != nil {
+		return nil, err
+	}
+
+	return stack, nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/console"
+)
+
+// readPassphrase prompts for a passphrase on the terminal, optionally
+// asking for confirmation (used by account creation/import).
+func readPassphrase(confirm bool) (string, error) {
+	passphrase, err := console.Stdin.PromptPassword("Passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("error reading passphrase: %v", err)
+	}
+	if !confirm {
+		return passphrase, nil
+	}
+	confirmation, err := console.Stdin.PromptPassword("Repeat passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("error reading passphrase: %v", err)
+	}
+	if passphrase != confirmation {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
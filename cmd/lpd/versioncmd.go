@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"gopkg.in/urfave/cli.v1"
+)
+
+var versionCommand = cli.Command{
+	Name:   "version",
+	Usage:  "print version number",
+	Action: printVersion,
+}
+
+func printVersion(ctx *cli.Context) error {
+	fmt.Println("lpd version", versionString())
+	return nil
+}
@@ -59,24 +59,80 @@ type Config struct {
 	RTMPPort   string
 	FFMpegPath string
 	VodPath    string
+	// Transcoding describes the HLS/DASH/mp4 renditions lpd should
+	// produce for each ingest; see transcoding.go.
+	Transcoding *TranscodingConfig
+	// Version is the config.json schema version. It lets Load() run the
+	// right chain of migrations on older datadirs instead of failing or
+	// silently misreading renamed/split fields; see migration.go.
+	Version int
+}
+
+// Options holds everything NewConfig needs to assemble a Config. It is
+// passed as a struct rather than positional arguments so that new
+// Livepeer-specific fields (RTMPPort, FFMpegPath, VodPath, and whatever
+// follows) can be added without churning every call site.
+type Options struct {
+	Path string
+	// Contract is the Swap payment contract address.
+	Contract common.Address
+	// Address is the account whose key KeySource should unlock. It may
+	// be the zero address for key sources, such as MemoryKeySource, that
+	// don't need one to resolve a key.
+	Address common.Address
+	// KeySource resolves the node's private key on demand, so the raw
+	// key only ever exists transiently in memory for as long as it takes
+	// to derive BzzKey and hand it to Swap; see keysource.go.
+	KeySource  KeySource
+	Passphrase string
+	NetworkId  uint64
+	RTMPPort   string
+	FFMpegPath string
+	VodPath    string
 }
 
 // config is agnostic to where private key is coming from
 // so managing accounts is outside swarm and left to wrappers
-func NewConfig(path string, contract common.Address, prvKey *ecdsa.PrivateKey, networkId uint64, rtmpPort string, ffmpegPath string) (self *Config, err error) {
-	glog.Infof("Config: RTMP Port: %v", rtmpPort)
-	address := crypto.PubkeyToAddress(prvKey.PublicKey) // default beneficiary address
-	dirpath := filepath.Join(path, "bzz-"+common.Bytes2Hex(address.Bytes()))
+func NewConfig(o *Options) (self *Config, err error) {
+	glog.Infof("Config: RTMP Port: %v", o.RTMPPort)
+	if err = o.KeySource.Unlock(o.Address, o.Passphrase); err != nil {
+		return nil, fmt.Errorf("error unlocking key: %v", err)
+	}
+	pub, err := o.KeySource.PublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("error reading public key: %v", err)
+	}
+	if err = verifyKeySource(o.KeySource, pub); err != nil {
+		return nil, err
+	}
+	// Swap is a vendored package that only accepts a raw *ecdsa.PrivateKey
+	// and has no sign-by-reference hook, so it can only be wired up for
+	// KeySources willing to hand one to this process; see localKeyMaterial.
+	// Other backends (e.g. ExternalKeySource) still produce a usable
+	// Config, just with Swap left disabled.
+	var prvKey *ecdsa.PrivateKey
+	if local, ok := o.KeySource.(localKeyMaterial); ok {
+		if prvKey, err = local.localPrivateKey(); err != nil {
+			return nil, fmt.Errorf("error reading local key for swap: %v", err)
+		}
+	}
+
+	address := crypto.PubkeyToAddress(*pub) // default beneficiary address
+	dirpath := filepath.Join(o.Path, "bzz-"+common.Bytes2Hex(address.Bytes()))
 	err = os.MkdirAll(dirpath, os.ModePerm)
 	if err != nil {
 		return
 	}
 	confpath := filepath.Join(dirpath, "config.json")
-	var data []byte
-	pubkey := crypto.FromECDSAPub(&prvKey.PublicKey)
+	pubkey := crypto.FromECDSAPub(pub)
 	pubkeyhex := common.ToHex(pubkey)
 	keyhex := crypto.Sha3Hash(pubkey).Hex()
 
+	vodPath := o.VodPath
+	if vodPath == "" {
+		vodPath = strings.Replace(o.Path, "livepeernet/livepeer", "vod", -1)
+	}
+
 	self = &Config{
 		SyncParams:    network.NewSyncParams(dirpath),
 		HiveParams:    network.NewHiveParams(dirpath),
@@ -84,23 +140,29 @@ func NewConfig(path string, contract common.Address, prvKey *ecdsa.PrivateKey, n
 		StoreParams:   storage.NewStoreParams(dirpath),
 		Port:          port,
 		Path:          dirpath,
-		Swap:          swap.DefaultSwapParams(contract, prvKey),
 		PublicKey:     pubkeyhex,
 		BzzKey:        keyhex,
 		EnsRoot:       ensRootAddress,
-		NetworkId:     networkId,
-		RTMPPort:      rtmpPort,
-		FFMpegPath:    ffmpegPath,
-		VodPath:       strings.Replace(path, "livepeernet/livepeer", "vod", -1),
+		NetworkId:     o.NetworkId,
+		RTMPPort:      o.RTMPPort,
+		FFMpegPath:    o.FFMpegPath,
+		VodPath:       vodPath,
+		Transcoding:   defaultTranscodingConfig(vodPath),
+		Version:       configVersion,
+	}
+	if prvKey != nil {
+		self.Swap = swap.DefaultSwapParams(o.Contract, prvKey)
 	}
 
-	data, err = ioutil.ReadFile(confpath)
-
+	_, err = os.Stat(confpath)
 	if err != nil {
 		if !os.IsNotExist(err) {
 			return
 		}
 		// file does not exist
+		if err = validateFFMpeg(self.FFMpegPath, self.Transcoding.Profiles); err != nil {
+			return nil, err
+		}
 		// write out config file
 		err = self.Save()
 		if err != nil {
@@ -108,12 +170,26 @@ func NewConfig(path string, contract common.Address, prvKey *ecdsa.PrivateKey, n
 		}
 		return
 	}
-	// file exists, deserialise
-	err = json.Unmarshal(data, self)
+	// file exists: migrate it forward to configVersion, then deserialise
+	err = self.Load(confpath)
 
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse config: %v", err)
 	}
+	// o already reflects the fully resolved defaults < config.json < env
+	// < flags hierarchy for these fields (the caller merges config.json
+	// itself before calling NewConfig), so restore them over whatever
+	// Load just deserialised from disk rather than letting the file win.
+	self.NetworkId = o.NetworkId
+	self.RTMPPort = o.RTMPPort
+	self.FFMpegPath = o.FFMpegPath
+	self.VodPath = o.VodPath
+	// Validate against the profiles actually loaded from disk, not the
+	// freshly-seeded defaults above - a custom profile in config.json
+	// must be checked against the installed ffmpeg before it's ever used.
+	if err = validateFFMpeg(self.FFMpegPath, self.Transcoding.Profiles); err != nil {
+		return nil, err
+	}
 	// check public key
 	if pubkeyhex != self.PublicKey {
 		return nil, fmt.Errorf("public key does not match the one in the config file %v != %v", pubkeyhex, self.PublicKey)
@@ -121,7 +197,13 @@ func NewConfig(path string, contract common.Address, prvKey *ecdsa.PrivateKey, n
 	if keyhex != self.BzzKey {
 		return nil, fmt.Errorf("bzz key does not match the one in the config file %v != %v", keyhex, self.BzzKey)
 	}
-	self.Swap.SetKey(prvKey)
+	// self.Swap may be non-nil here even when prvKey is nil: Load just
+	// deserialised whatever Swap state a prior run with a local key
+	// source persisted to this same config.json. Only rekey it when this
+	// run actually has a local key to hand over.
+	if prvKey != nil && self.Swap != nil {
+		self.Swap.SetKey(prvKey)
+	}
 
 	if (self.EnsRoot == common.Address{}) {
 		self.EnsRoot = ensRootAddress
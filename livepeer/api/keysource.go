@@ -0,0 +1,201 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// KeySource abstracts where the node's private key material comes from.
+// It never hands back raw key material: NewConfig derives BzzKey from
+// PublicKey and proves the backend actually holds the matching key by
+// calling Sign, so Config itself never has to carry a private key
+// through the life of a long-running process.
+type KeySource interface {
+	// Unlock prepares addr's key for use, prompting for or otherwise
+	// obtaining a passphrase as the implementation requires.
+	Unlock(addr common.Address, passphrase string) error
+	// PublicKey returns the public key of the account last unlocked.
+	PublicKey() (*ecdsa.PublicKey, error)
+	// Sign signs hash with the key last unlocked.
+	Sign(hash []byte) ([]byte, error)
+}
+
+// localKeyMaterial is implemented by KeySource backends whose private key
+// already lives decrypted in this process once unlocked, so handing it
+// to the vendored swap package - which only accepts a raw
+// *ecdsa.PrivateKey and has no sign-by-reference hook yet - doesn't cross
+// any new trust boundary. ExternalKeySource deliberately does not
+// implement this: a remote- or hardware-wallet-backed key should never
+// materialize in the node process, so Swap simply isn't wired up for
+// those accounts until the vendored swap package grows that hook.
+type localKeyMaterial interface {
+	localPrivateKey() (*ecdsa.PrivateKey, error)
+}
+
+// keyVerificationHash is a fixed, meaningless challenge NewConfig signs
+// once at startup to make sure a KeySource's Sign and PublicKey agree -
+// cheap insurance against a backend returning inconsistent key material.
+var keyVerificationHash = crypto.Sha3Hash([]byte("lpd/keysource/verify"))
+
+// verifyKeySource signs keyVerificationHash with ks and checks that the
+// signature recovers to pub.
+func verifyKeySource(ks KeySource, pub *ecdsa.PublicKey) error {
+	sig, err := ks.Sign(keyVerificationHash.Bytes())
+	if err != nil {
+		return fmt.Errorf("error verifying key source: %v", err)
+	}
+	recovered, err := crypto.SigToPub(keyVerificationHash.Bytes(), sig)
+	if err != nil {
+		return fmt.Errorf("error recovering signature: %v", err)
+	}
+	if crypto.PubkeyToAddress(*recovered) != crypto.PubkeyToAddress(*pub) {
+		return fmt.Errorf("key source: Sign does not match PublicKey")
+	}
+	return nil
+}
+
+// KeystoreKeySource resolves keys from an on-disk encrypted JSON keystore
+// compatible with go-ethereum's v3 format.
+type KeystoreKeySource struct {
+	ks         *keystore.KeyStore
+	account    accounts.Account
+	passphrase string
+}
+
+// NewKeystoreKeySource opens (creating if necessary) the v3 keystore at
+// keydir.
+func NewKeystoreKeySource(keydir string) *KeystoreKeySource {
+	return &KeystoreKeySource{
+		ks: keystore.NewKeyStore(keydir, keystore.StandardScryptN, keystore.StandardScryptP),
+	}
+}
+
+func (k *KeystoreKeySource) Unlock(addr common.Address, passphrase string) error {
+	account, err := k.ks.Find(accounts.Account{Address: addr})
+	if err != nil {
+		return fmt.Errorf("account %s not found in keystore: %v", addr.Hex(), err)
+	}
+	if err := k.ks.Unlock(account, passphrase); err != nil {
+		return fmt.Errorf("error unlocking account: %v", err)
+	}
+	k.account = account
+	k.passphrase = passphrase
+	return nil
+}
+
+func (k *KeystoreKeySource) PublicKey() (*ecdsa.PublicKey, error) {
+	sig, err := k.Sign(keyVerificationHash.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("keystore key source: error deriving public key: %v", err)
+	}
+	return crypto.SigToPub(keyVerificationHash.Bytes(), sig)
+}
+
+func (k *KeystoreKeySource) Sign(hash []byte) ([]byte, error) {
+	return k.ks.SignHash(k.account, hash)
+}
+
+// localPrivateKey satisfies localKeyMaterial. KeyStore deliberately
+// doesn't hand out raw keys through SignHash/Unlock, so this re-exports
+// the already-unlocked account under the same passphrase - no new
+// exposure, since that key material never leaves this process either
+// way.
+func (k *KeystoreKeySource) localPrivateKey() (*ecdsa.PrivateKey, error) {
+	keyjson, err := k.ks.Export(k.account, k.passphrase, k.passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("error exporting key: %v", err)
+	}
+	key, err := keystore.DecryptKey(keyjson, k.passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting key: %v", err)
+	}
+	return key.PrivateKey, nil
+}
+
+// MemoryKeySource wraps an already-available key, with no unlocking or
+// persistence involved. It exists for tests and for the ephemeral,
+// throwaway identities lpd mints when run without an --account.
+type MemoryKeySource struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewMemoryKeySource wraps key as a KeySource.
+func NewMemoryKeySource(key *ecdsa.PrivateKey) *MemoryKeySource {
+	return &MemoryKeySource{key: key}
+}
+
+func (m *MemoryKeySource) Unlock(addr common.Address, passphrase string) error {
+	return nil
+}
+
+func (m *MemoryKeySource) PublicKey() (*ecdsa.PublicKey, error) {
+	return &m.key.PublicKey, nil
+}
+
+func (m *MemoryKeySource) Sign(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, m.key)
+}
+
+func (m *MemoryKeySource) localPrivateKey() (*ecdsa.PrivateKey, error) {
+	return m.key, nil
+}
+
+// ExternalKeySource resolves and signs with a key held by a remote
+// signer reached over IPC/HTTP-RPC (a software remote-signing daemon, or
+// a gateway in front of a hardware wallet). It is intended for setups
+// where a passphrase-protected keystore on the same disk as the node
+// isn't an acceptable trust boundary, so unlike KeystoreKeySource and
+// MemoryKeySource it never asks the remote for raw key material - only
+// for a public key and signatures - and does not implement
+// localKeyMaterial. Swap is not available for accounts backed by an
+// ExternalKeySource until the vendored swap package can sign through a
+// callback instead of holding a raw key.
+type ExternalKeySource struct {
+	client *rpc.Client
+	addr   common.Address
+}
+
+// NewExternalKeySource dials the remote signer at endpoint (an IPC
+// socket path or HTTP-RPC URL).
+func NewExternalKeySource(endpoint string) (*ExternalKeySource, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("error dialing external signer: %v", err)
+	}
+	return &ExternalKeySource{client: client}, nil
+}
+
+func (e *ExternalKeySource) Unlock(addr common.Address, passphrase string) error {
+	var unlocked bool
+	if err := e.client.Call(&unlocked, "account_unlock", addr, passphrase); err != nil {
+		return fmt.Errorf("external signer: error unlocking %s: %v", addr.Hex(), err)
+	}
+	if !unlocked {
+		return fmt.Errorf("external signer refused to unlock %s", addr.Hex())
+	}
+	e.addr = addr
+	return nil
+}
+
+func (e *ExternalKeySource) PublicKey() (*ecdsa.PublicKey, error) {
+	var pubHex hexutil.Bytes
+	if err := e.client.Call(&pubHex, "account_publicKey", e.addr); err != nil {
+		return nil, fmt.Errorf("external signer: error fetching public key: %v", err)
+	}
+	return crypto.UnmarshalPubkey(pubHex)
+}
+
+func (e *ExternalKeySource) Sign(hash []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	if err := e.client.Call(&sig, "account_sign", e.addr, hexutil.Bytes(hash)); err != nil {
+		return nil, fmt.Errorf("external signer: error signing: %v", err)
+	}
+	return sig, nil
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configVersion is the current config.json schema version. Bump it and
+// add a migration to migrations below whenever Config gains, loses, or
+// renames a persisted field.
+const configVersion = 2
+
+// migration upgrades a raw config.json, keyed by field name, from the
+// version it is registered under to the next one. Migrations only ever
+// move forward: rename, split, or default-fill fields, never drop
+// information silently.
+type migration func(map[string]json.RawMessage) (map[string]json.RawMessage, error)
+
+// migrations is keyed by the version a config is migrating *from*. Load
+// applies them in order until the config reaches configVersion.
+var migrations = map[int]migration{
+	0: migrateToV1,
+	1: migrateToV2,
+}
+
+// migrateToV1 promotes the VOD path to a first-class, persisted field.
+// Configs written before versioning existed never stored it; NewConfig
+// derived it on every startup via
+// strings.Replace(path, "livepeernet/livepeer", "vod", -1). From v1 on,
+// whatever is on disk is authoritative.
+func migrateToV1(raw map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	if _, ok := raw["VodPath"]; ok {
+		return raw, nil
+	}
+	var dirpath string
+	if pathRaw, ok := raw["Path"]; ok {
+		if err := json.Unmarshal(pathRaw, &dirpath); err != nil {
+			return nil, fmt.Errorf("migrateToV1: bad Path: %v", err)
+		}
+	}
+	// The persisted Path is the post-join bzz-<addr> directory (see
+	// NewConfig), but the original VodPath formula was always computed
+	// from the base datadir one level up from that. Strip the bzz-<addr>
+	// suffix before reapplying it, or this would derive a VodPath nested
+	// under the wrong directory for every pre-versioning datadir.
+	basePath := filepath.Dir(dirpath)
+	vodPath, err := json.Marshal(strings.Replace(basePath, "livepeernet/livepeer", "vod", -1))
+	if err != nil {
+		return nil, err
+	}
+	raw["VodPath"] = vodPath
+	return raw, nil
+}
+
+// migrateToV2 default-fills Transcoding for configs written before
+// transcoding profiles existed, seeding them with the same 240p/480p/720p
+// HLS ladder NewConfig gives a fresh datadir.
+func migrateToV2(raw map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	if _, ok := raw["Transcoding"]; ok {
+		return raw, nil
+	}
+	var outputDir string
+	if vodPathRaw, ok := raw["VodPath"]; ok {
+		if err := json.Unmarshal(vodPathRaw, &outputDir); err != nil {
+			return nil, fmt.Errorf("migrateToV2: bad VodPath: %v", err)
+		}
+	}
+	transcoding, err := json.Marshal(defaultTranscodingConfig(outputDir))
+	if err != nil {
+		return nil, err
+	}
+	raw["Transcoding"] = transcoding
+	return raw, nil
+}
+
+// Load reads confpath, migrates it forward to configVersion if
+// necessary, and unmarshals the result into self. If any migration ran,
+// the pre-migration file is preserved as confpath+".bak" and the
+// upgraded config is written back atomically (temp file + rename).
+func (self *Config) Load(confpath string) error {
+	data, err := ioutil.ReadFile(confpath)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("unable to parse config: %v", err)
+	}
+
+	version := 0
+	if v, ok := raw["Version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return fmt.Errorf("unable to parse config version: %v", err)
+		}
+	}
+
+	migrated := false
+	for version < configVersion {
+		migrate, ok := migrations[version]
+		if !ok {
+			return fmt.Errorf("no migration registered from config version %d", version)
+		}
+		raw, err = migrate(raw)
+		if err != nil {
+			return fmt.Errorf("error migrating config from version %d: %v", version, err)
+		}
+		version++
+		migrated = true
+	}
+
+	if migrated {
+		versionRaw, err := json.Marshal(version)
+		if err != nil {
+			return err
+		}
+		raw["Version"] = versionRaw
+		if err := writeMigratedConfig(confpath, data, raw); err != nil {
+			return err
+		}
+	}
+
+	merged, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(merged, self)
+}
+
+// writeMigratedConfig backs up the pre-migration bytes to confpath+".bak"
+// and atomically replaces confpath with the migrated config (temp file +
+// rename, so a crash mid-write never leaves a half-written config.json).
+func writeMigratedConfig(confpath string, original []byte, raw map[string]json.RawMessage) error {
+	if err := ioutil.WriteFile(confpath+".bak", original, os.ModePerm); err != nil {
+		return fmt.Errorf("error backing up config: %v", err)
+	}
+
+	data, err := json.MarshalIndent(raw, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmppath := confpath + ".tmp"
+	if err := ioutil.WriteFile(tmppath, data, os.ModePerm); err != nil {
+		return fmt.Errorf("error writing migrated config: %v", err)
+	}
+	return os.Rename(tmppath, filepath.Clean(confpath))
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Container is the packaging format a TranscodeProfile's output segments
+// are written in.
+type Container string
+
+const (
+	HLS  Container = "hls"
+	DASH Container = "dash"
+	MP4  Container = "mp4"
+)
+
+// TranscodeProfile describes a single output rendition of an ingested
+// stream: the video/audio codecs and bitrates ffmpeg should encode to,
+// and the container its segments are packaged in.
+type TranscodeProfile struct {
+	Name            string
+	VideoCodec      string
+	Width           int
+	Height          int
+	Bitrate         int // kbps
+	Framerate       int
+	AudioCodec      string
+	AudioBitrate    int // kbps
+	SegmentDuration int // seconds
+	Container       Container
+}
+
+// TranscodingConfig describes everything lpd needs to transcode an
+// ingested stream into one or more renditions and publish the resulting
+// segments into swarm storage under a manifest key.
+type TranscodingConfig struct {
+	Profiles []TranscodeProfile
+	// HLSPort is the port the HLS/DASH segment server listens on.
+	HLSPort string
+	// OutputDir is where transcoded segments are written before being
+	// published into swarm storage.
+	OutputDir string
+	// KeyframeInterval is the number of frames between keyframes,
+	// forced so every rendition can cut a segment at the same point.
+	KeyframeInterval int
+}
+
+const defaultHLSPort = "8935"
+
+// defaultTranscodingConfig returns the default 240p/480p/720p HLS ladder,
+// the starting point NewConfig seeds every fresh datadir with.
+func defaultTranscodingConfig(outputDir string) *TranscodingConfig {
+	return &TranscodingConfig{
+		HLSPort:          defaultHLSPort,
+		OutputDir:        outputDir,
+		KeyframeInterval: 60,
+		Profiles: []TranscodeProfile{
+			{Name: "240p", VideoCodec: "libx264", Width: 426, Height: 240, Bitrate: 400, Framerate: 30, AudioCodec: "aac", AudioBitrate: 64, SegmentDuration: 2, Container: HLS},
+			{Name: "480p", VideoCodec: "libx264", Width: 854, Height: 480, Bitrate: 1000, Framerate: 30, AudioCodec: "aac", AudioBitrate: 128, SegmentDuration: 2, Container: HLS},
+			{Name: "720p", VideoCodec: "libx264", Width: 1280, Height: 720, Bitrate: 2500, Framerate: 30, AudioCodec: "aac", AudioBitrate: 128, SegmentDuration: 2, Container: HLS},
+		},
+	}
+}
+
+// validateFFMpeg resolves ffmpegPath and probes `ffmpeg -codecs` to make
+// sure every codec referenced by profiles is actually available, so a
+// misconfigured node fails fast at startup rather than mid-stream.
+func validateFFMpeg(ffmpegPath string, profiles []TranscodeProfile) error {
+	resolved, err := exec.LookPath(ffmpegPath)
+	if err != nil {
+		return fmt.Errorf("ffmpeg not found at %q: %v", ffmpegPath, err)
+	}
+
+	out, err := exec.Command(resolved, "-codecs").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error probing ffmpeg codecs: %v", err)
+	}
+	codecs := string(out)
+
+	for _, p := range profiles {
+		if !strings.Contains(codecs, p.VideoCodec) {
+			return fmt.Errorf("ffmpeg at %q does not support video codec %q required by profile %q", resolved, p.VideoCodec, p.Name)
+		}
+		if p.AudioCodec != "" && !strings.Contains(codecs, p.AudioCodec) {
+			return fmt.Errorf("ffmpeg at %q does not support audio codec %q required by profile %q", resolved, p.AudioCodec, p.Name)
+		}
+	}
+	return nil
+}